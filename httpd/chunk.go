@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/textproto"
 	"strconv"
+	"strings"
 )
 
 type chunkReader struct {
@@ -14,6 +16,10 @@ type chunkReader struct {
 	bufr *bufio.Reader
 	done bool
 	crlf [2]byte
+
+	// req, when set, receives parsed trailer headers in req.Trailer, filtered
+	// down to the names req.Header announced via the Trailer request header.
+	req *Request
 }
 
 func (cw *chunkReader) Read(p []byte) (n int, err error) {
@@ -28,7 +34,7 @@ func (cw *chunkReader) Read(p []byte) (n int, err error) {
 	}
 	if cw.n == 0 {
 		cw.done = true
-		err = cw.discardCRLF()
+		err = cw.readTrailer()
 		return
 	}
 	if len(p) <= cw.n {
@@ -45,6 +51,32 @@ func (cw *chunkReader) Read(p []byte) (n int, err error) {
 	return
 }
 
+// readTrailer consumes the trailer header block (and its terminating blank
+// line) that follows the final zero-size chunk, keeping only the header
+// names the request's Trailer header pre-announced.
+func (cw *chunkReader) readTrailer() error {
+	trailer, err := readHeader(cw.bufr)
+	if err != nil {
+		return err
+	}
+	if cw.req == nil || len(trailer) == 0 {
+		return nil
+	}
+	for _, name := range strings.Split(cw.req.Header.Get("Trailer"), ",") {
+		name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if v, ok := trailer[name]; ok {
+			if cw.req.Trailer == nil {
+				cw.req.Trailer = make(Header)
+			}
+			cw.req.Trailer[name] = v
+		}
+	}
+	return nil
+}
+
 func (cw *chunkReader) discardCRLF() (err error) {
 	if _, err = io.ReadFull(cw.bufr, cw.crlf[:]); err != nil {
 		if cw.crlf[0] != '\r' || cw.crlf[1] != '\n' {
@@ -88,7 +120,7 @@ func (cw *chunkWriter) Write(p []byte) (n int, err error) {
 		}
 		cw.wrote = true
 	}
-	bufw := cw.resp.bufw
+	bufw := cw.resp.c.bufw
 	if cw.resp.chunking {
 		_, err = fmt.Fprintf(bufw, "%x\r\n", len(p))
 		if err != nil {
@@ -125,6 +157,13 @@ func (cw *chunkWriter) finalizeHeader(p []byte) {
 }
 
 func (cw *chunkWriter) writeHeader() error {
+	if cw.resp.chunking && len(cw.resp.trailer) > 0 {
+		names := make([]string, 0, len(cw.resp.trailer))
+		for name := range cw.resp.trailer {
+			names = append(names, name)
+		}
+		cw.resp.header.Set("Trailer", strings.Join(names, ", "))
+	}
 	codeString := strconv.Itoa(cw.resp.statusCode)
 	statusLine := cw.resp.req.Proto + " " + codeString + " " + statusText[cw.resp.statusCode] + "\r\n"
 	bufw := cw.resp.c.bufw
@@ -132,10 +171,12 @@ func (cw *chunkWriter) writeHeader() error {
 	if err != nil {
 		return err
 	}
-	for k, v := range cw.resp.header {
-		_, err = bufw.WriteString(k + ": " + v[0] + "\r\n")
-		if err != nil {
-			return err
+	for k, vs := range cw.resp.header {
+		for _, v := range vs {
+			_, err = bufw.WriteString(k + ": " + v + "\r\n")
+			if err != nil {
+				return err
+			}
 		}
 	}
 	_, err = bufw.WriteString("\r\n")
@@ -0,0 +1,102 @@
+package httpd
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestChunkReaderReadsAnnouncedTrailer(t *testing.T) {
+	raw := "5\r\nhello\r\n0\r\nX-Checksum: abc123\r\nX-Ignored: nope\r\n\r\n"
+	req := &Request{Header: Header{"Trailer": {"X-Checksum"}}}
+	cr := &chunkReader{bufr: bufio.NewReader(strings.NewReader(raw)), req: req}
+
+	buf := make([]byte, 64)
+	n, err := cr.Read(buf)
+	if err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("Read body = (%q, %v), want (%q, nil)", buf[:n], err, "hello")
+	}
+
+	// The terminating zero-size chunk consumes the trailer block but reports
+	// no error itself; io.EOF only surfaces on the following call.
+	if n, err := cr.Read(buf); n != 0 || err != nil {
+		t.Fatalf("Read at terminating chunk = (%d, %v), want (0, nil)", n, err)
+	}
+	if _, err := cr.Read(buf); err != io.EOF {
+		t.Fatalf("Read after terminating chunk: err = %v, want io.EOF", err)
+	}
+
+	if got := req.Trailer.Get("X-Checksum"); got != "abc123" {
+		t.Errorf("Trailer.Get(X-Checksum) = %q, want %q", got, "abc123")
+	}
+	if got := req.Trailer.Get("X-Ignored"); got != "" {
+		t.Errorf("Trailer should only retain announced names, got X-Ignored=%q", got)
+	}
+}
+
+func TestChunkReaderNoTrailerDeclared(t *testing.T) {
+	raw := "5\r\nhello\r\n0\r\n\r\n"
+	req := &Request{Header: make(Header)}
+	cr := &chunkReader{bufr: bufio.NewReader(strings.NewReader(raw)), req: req}
+
+	buf := make([]byte, 64)
+	if _, err := cr.Read(buf); err != nil {
+		t.Fatalf("Read body: %v", err)
+	}
+	if n, err := cr.Read(buf); n != 0 || err != nil {
+		t.Fatalf("Read at terminating chunk = (%d, %v), want (0, nil)", n, err)
+	}
+	if _, err := cr.Read(buf); err != io.EOF {
+		t.Fatalf("Read after terminating chunk: err = %v, want io.EOF", err)
+	}
+	if req.Trailer != nil {
+		t.Errorf("Trailer = %v, want nil when none was announced", req.Trailer)
+	}
+}
+
+// TestChunkWriterWritesTrailerBlock drives a response through a real conn
+// (backed by a net.Pipe) and checks the response-writing half of trailer
+// support: chunkWriter.writeHeader echoes the declared trailer names into
+// the Trailer response header, and finishRequest writes the terminating
+// "0\r\n" chunk followed by the trailer's header lines and a final blank
+// line.
+func TestChunkWriterWritesTrailerBlock(t *testing.T) {
+	client, server := net.Pipe()
+	c := newConn(server, &Server{})
+	req := &Request{Header: make(Header), Proto: "HTTP/1.1", conn: c, Body: strings.NewReader("")}
+	resp := setupResponse(c, req)
+	resp.TrailerHeader().Set("X-Checksum", "abc123")
+
+	raw := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(client)
+		raw <- string(b)
+	}()
+
+	if _, err := resp.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Force the header and body chunk out to the connection now, while
+	// handlerDone is still false, so finalizeHeader picks chunking instead
+	// of buffering the whole body and emitting Content-Length.
+	if err := resp.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := req.finishRequest(resp); err != nil {
+		t.Fatalf("finishRequest: %v", err)
+	}
+	server.Close()
+
+	out := <-raw
+	if !strings.Contains(out, "Trailer: X-Checksum\r\n") {
+		t.Errorf("response headers missing trailer announcement, got %q", out)
+	}
+	if !strings.Contains(out, "5\r\nhello\r\n") {
+		t.Errorf("response missing body chunk, got %q", out)
+	}
+	if !strings.Contains(out, "0\r\nX-Checksum: abc123\r\n\r\n") {
+		t.Errorf("response missing terminating chunk + trailer block, got %q", out)
+	}
+}
@@ -0,0 +1,164 @@
+package httpd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SameSite controls whether a cookie is sent with cross-site requests,
+// mirroring the attribute of the same name from RFC 6265bis.
+type SameSite int
+
+const (
+	SameSiteDefault SameSite = iota
+	SameSiteLax
+	SameSiteStrict
+	SameSiteNone
+)
+
+// Cookie represents an HTTP cookie as sent in a Set-Cookie response header
+// or parsed from a request's Cookie header.
+type Cookie struct {
+	Name  string
+	Value string
+
+	Path    string
+	Domain  string
+	Expires time.Time
+	MaxAge  int
+
+	Secure   bool
+	HttpOnly bool
+	SameSite SameSite
+}
+
+// ErrNoCookie is returned by CookieByName when the named cookie isn't
+// present on the request.
+var ErrNoCookie = errors.New("httpd: named cookie not present")
+
+// Cookies returns the cookies sent with the request.
+func (r *Request) Cookies() []*Cookie {
+	if r.cookies == nil {
+		r.parseCookies()
+	}
+	return r.cookieList
+}
+
+// CookieByName returns the named cookie, or ErrNoCookie if it isn't present.
+func (r *Request) CookieByName(name string) (*Cookie, error) {
+	for _, c := range r.Cookies() {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, ErrNoCookie
+}
+
+// unquoteCookieValue strips a single layer of RFC 6265 DQUOTE-wrapping from
+// a cookie-value, if present.
+func unquoteCookieValue(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// SetCookie appends a Set-Cookie header for c to w's response headers.
+func SetCookie(w ResponseWriter, c *Cookie) {
+	if v := c.String(); v != "" {
+		w.Header().Add("Set-Cookie", v)
+	}
+}
+
+// String serializes c into the Set-Cookie header syntax, quoting Value
+// when it contains characters that aren't valid in a bare cookie-value.
+func (c *Cookie) String() string {
+	if c == nil || c.Name == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(c.Name)
+	b.WriteByte('=')
+	b.WriteString(sanitizeCookieValue(c.Value))
+
+	if c.Path != "" {
+		fmt.Fprintf(&b, "; Path=%s", c.Path)
+	}
+	if c.Domain != "" {
+		fmt.Fprintf(&b, "; Domain=%s", c.Domain)
+	}
+	if !c.Expires.IsZero() {
+		fmt.Fprintf(&b, "; Expires=%s", c.Expires.UTC().Format(http.TimeFormat))
+	}
+	if c.MaxAge > 0 {
+		fmt.Fprintf(&b, "; Max-Age=%d", c.MaxAge)
+	} else if c.MaxAge < 0 {
+		// A negative MaxAge requests immediate deletion.
+		b.WriteString("; Max-Age=0")
+	}
+	if c.Secure {
+		b.WriteString("; Secure")
+	}
+	if c.HttpOnly {
+		b.WriteString("; HttpOnly")
+	}
+	switch c.SameSite {
+	case SameSiteLax:
+		b.WriteString("; SameSite=Lax")
+	case SameSiteStrict:
+		b.WriteString("; SameSite=Strict")
+	case SameSiteNone:
+		b.WriteString("; SameSite=None")
+	}
+	return b.String()
+}
+
+// sanitizeCookieValue quotes v if it contains a character that isn't valid
+// in a bare RFC 6265 cookie-octet.
+func sanitizeCookieValue(v string) string {
+	for i := 0; i < len(v); i++ {
+		b := v[i]
+		if b < 0x21 || b == 0x22 || b == 0x2c || b == 0x3b || b == 0x5c || b >= 0x7f {
+			return `"` + v + `"`
+		}
+	}
+	return v
+}
+
+// SignedCookie returns a copy of c whose Value has been HMAC-SHA256 signed
+// with key (typically Server.CookieSignKey), so VerifyCookie can later
+// detect if the value was tampered with client-side.
+func SignedCookie(key []byte, c *Cookie) *Cookie {
+	signed := *c
+	signed.Value = base64.RawURLEncoding.EncodeToString([]byte(c.Value)) + "." + signCookieValue(key, c.Value)
+	return &signed
+}
+
+// VerifyCookie checks the signature SignedCookie attached to c's Value
+// against key and, if it matches, returns the original unsigned value.
+func VerifyCookie(key []byte, c *Cookie) (string, error) {
+	dot := strings.LastIndexByte(c.Value, '.')
+	if dot == -1 {
+		return "", errors.New("httpd: malformed signed cookie")
+	}
+	rawValue, err := base64.RawURLEncoding.DecodeString(c.Value[:dot])
+	if err != nil {
+		return "", errors.New("httpd: malformed signed cookie")
+	}
+	if !hmac.Equal([]byte(signCookieValue(key, string(rawValue))), []byte(c.Value[dot+1:])) {
+		return "", errors.New("httpd: signed cookie signature mismatch")
+	}
+	return string(rawValue), nil
+}
+
+func signCookieValue(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
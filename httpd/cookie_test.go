@@ -0,0 +1,99 @@
+package httpd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCookieString(t *testing.T) {
+	c := &Cookie{
+		Name:     "session",
+		Value:    "abc123",
+		Path:     "/",
+		Domain:   "example.com",
+		Expires:  time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		MaxAge:   3600,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: SameSiteLax,
+	}
+	want := "session=abc123; Path=/; Domain=example.com; Expires=Wed, 02 Jan 2030 03:04:05 GMT; Max-Age=3600; Secure; HttpOnly; SameSite=Lax"
+	if got := c.String(); got != want {
+		t.Errorf("String() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCookieStringDeletion(t *testing.T) {
+	c := &Cookie{Name: "session", Value: "", MaxAge: -1}
+	want := "session=; Max-Age=0"
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCookieStringQuotesSpecialValue(t *testing.T) {
+	c := &Cookie{Name: "k", Value: "has space"}
+	want := `k="has space"`
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSignedCookieVerifyCookieRoundTrip(t *testing.T) {
+	key := []byte("super-secret-key")
+	c := &Cookie{Name: "session", Value: "user-42"}
+
+	signed := SignedCookie(key, c)
+	got, err := VerifyCookie(key, signed)
+	if err != nil {
+		t.Fatalf("VerifyCookie: %v", err)
+	}
+	if got != "user-42" {
+		t.Errorf("VerifyCookie = %q, want %q", got, "user-42")
+	}
+}
+
+func TestVerifyCookieRejectsTamperedValue(t *testing.T) {
+	key := []byte("super-secret-key")
+	signed := SignedCookie(key, &Cookie{Name: "session", Value: "user-42"})
+	tampered := &Cookie{Name: "session", Value: signed.Value + "x"}
+	if _, err := VerifyCookie(key, tampered); err == nil {
+		t.Fatal("VerifyCookie: want error for tampered value, got nil")
+	}
+}
+
+func TestVerifyCookieRejectsWrongKey(t *testing.T) {
+	signed := SignedCookie([]byte("key-one"), &Cookie{Name: "session", Value: "user-42"})
+	if _, err := VerifyCookie([]byte("key-two"), signed); err == nil {
+		t.Fatal("VerifyCookie: want error for wrong key, got nil")
+	}
+}
+
+func TestRequestCookiesMultipleHeaderLinesAndQuoting(t *testing.T) {
+	r := &Request{Header: Header{
+		"Cookie": {`a=1; b="has space"`, "c=3"},
+	}}
+
+	cookies := r.Cookies()
+	want := map[string]string{"a": "1", "b": "has space", "c": "3"}
+	if len(cookies) != len(want) {
+		t.Fatalf("Cookies() = %v, want %d entries", cookies, len(want))
+	}
+	for _, c := range cookies {
+		if want[c.Name] != c.Value {
+			t.Errorf("cookie %s = %q, want %q", c.Name, c.Value, want[c.Name])
+		}
+	}
+
+	b, err := r.CookieByName("b")
+	if err != nil {
+		t.Fatalf("CookieByName(b): %v", err)
+	}
+	if b.Value != "has space" {
+		t.Errorf("CookieByName(b).Value = %q, want %q", b.Value, "has space")
+	}
+
+	if _, err := r.CookieByName("missing"); err != ErrNoCookie {
+		t.Errorf("CookieByName(missing) err = %v, want ErrNoCookie", err)
+	}
+}
@@ -0,0 +1,246 @@
+package fcgi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"httpd/httpd"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Serve accepts FastCGI Responder-role connections on l and dispatches each
+// request to h, synthesizing an httpd.Request/ResponseWriter pair from the
+// FastCGI records. It blocks until l.Accept returns an error.
+func Serve(l net.Listener, h httpd.Handler) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveConn(c, h)
+	}
+}
+
+// activeRequest accumulates the records belonging to one FastCGI request id
+// until its params and stdin streams are both complete.
+type activeRequest struct {
+	params         map[string]string
+	paramsComplete bool
+	stdin          bytes.Buffer
+}
+
+func serveConn(c net.Conn, h httpd.Handler) {
+	defer c.Close()
+
+	var writeMu sync.Mutex // serializes record writes from concurrent requests
+	reqs := make(map[uint16]*activeRequest)
+	br := bufio.NewReader(c)
+
+	for {
+		rec, err := readRecord(br)
+		if err != nil {
+			return
+		}
+		switch rec.h.Type {
+		case typeBeginRequest:
+			begin, err := parseBeginRequest(rec.content)
+			if err != nil {
+				return
+			}
+			if begin.role != roleResponder {
+				writeMu.Lock()
+				writeEndRequest(c, rec.h.RequestId, 0, statusUnknownRole)
+				writeMu.Unlock()
+				continue
+			}
+			reqs[rec.h.RequestId] = &activeRequest{params: make(map[string]string)}
+
+		case typeParams:
+			req := reqs[rec.h.RequestId]
+			if req == nil {
+				continue
+			}
+			if len(rec.content) == 0 {
+				req.paramsComplete = true
+				continue
+			}
+			if err := parseParams(rec.content, req.params); err != nil {
+				return
+			}
+
+		case typeStdin:
+			req := reqs[rec.h.RequestId]
+			if req == nil {
+				continue
+			}
+			if len(rec.content) == 0 {
+				delete(reqs, rec.h.RequestId)
+				if !req.paramsComplete {
+					// Malformed per the FastCGI spec: stdin must not
+					// close until params has. Drop the request instead
+					// of dispatching it with a partial env.
+					continue
+				}
+				go handleRequest(&writeMu, c, rec.h.RequestId, req, h)
+				continue
+			}
+			req.stdin.Write(rec.content)
+
+		case typeAbortRequest:
+			delete(reqs, rec.h.RequestId)
+		}
+	}
+}
+
+func handleRequest(writeMu *sync.Mutex, c net.Conn, reqId uint16, req *activeRequest, h httpd.Handler) {
+	r, err := newRequest(req)
+	w := &response{id: reqId, conn: c, mu: writeMu, header: make(httpd.Header), statusCode: 200}
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		w.finish()
+		return
+	}
+	h.ServeHTTP(w, r)
+	w.finish()
+}
+
+// newRequest builds an httpd.Request out of the CGI params and stdin body
+// collected for one FastCGI request.
+func newRequest(req *activeRequest) (*httpd.Request, error) {
+	params := req.params
+
+	r := &httpd.Request{
+		Method:     params["REQUEST_METHOD"],
+		Proto:      params["SERVER_PROTOCOL"],
+		RemoteAddr: params["REMOTE_ADDR"],
+		RequestURI: params["REQUEST_URI"],
+		Header:     make(httpd.Header),
+		Body:       bytes.NewReader(req.stdin.Bytes()),
+	}
+	if r.Method == "" {
+		r.Method = "GET"
+	}
+	if r.Proto == "" {
+		r.Proto = "HTTP/1.1"
+	}
+
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		return nil, fmt.Errorf("fcgi: bad REQUEST_URI %q: %w", r.RequestURI, err)
+	}
+	r.URL = u
+
+	if cl := params["CONTENT_LENGTH"]; cl != "" {
+		r.Header.Set("Content-Length", cl)
+	}
+	if ct := params["CONTENT_TYPE"]; ct != "" {
+		r.Header.Set("Content-Type", ct)
+	}
+	for k, v := range params {
+		name, ok := httpHeaderName(k)
+		if !ok {
+			continue
+		}
+		r.Header.Add(name, v)
+	}
+	return r, nil
+}
+
+// httpHeaderName turns a CGI "HTTP_X_FORWARDED_FOR" env var name into the
+// HTTP header name "X-Forwarded-For".
+func httpHeaderName(envName string) (name string, ok bool) {
+	const prefix = "HTTP_"
+	if !strings.HasPrefix(envName, prefix) {
+		return "", false
+	}
+	parts := strings.Split(envName[len(prefix):], "_")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// statusUnknownRole is the FCGI_UNKNOWN_ROLE protocol status, used when the
+// web server begins a request with a role other than Responder.
+const statusUnknownRole = 3
+
+// response implements httpd.ResponseWriter over a FastCGI connection,
+// writing CGI-style response headers followed by FCGI_STDOUT records.
+type response struct {
+	id     uint16
+	conn   net.Conn
+	mu     *sync.Mutex
+	header httpd.Header
+
+	statusCode  int
+	wroteHeader bool
+	headerSent  bool
+}
+
+func (w *response) Header() httpd.Header {
+	return w.header
+}
+
+func (w *response) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.statusCode = statusCode
+	w.wroteHeader = true
+}
+
+func (w *response) Write(p []byte) (int, error) {
+	w.WriteHeader(200)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.flushHeaderLocked(); err != nil {
+		return 0, err
+	}
+	if err := writeRecord(w.conn, typeStdout, w.id, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *response) flushHeaderLocked() error {
+	if w.headerSent {
+		return nil
+	}
+	w.headerSent = true
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Status: %d %s\r\n", w.statusCode, cgiStatusText[w.statusCode])
+	for k, vs := range w.header {
+		for _, v := range vs {
+			fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+		}
+	}
+	buf.WriteString("\r\n")
+	return writeRecord(w.conn, typeStdout, w.id, buf.Bytes())
+}
+
+func (w *response) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushHeaderLocked()
+	writeRecord(w.conn, typeStdout, w.id, nil)
+	writeEndRequest(w.conn, w.id, 0, statusRequestComplete)
+}
+
+// cgiStatusText mirrors the reason phrases httpd.Server writes for status
+// lines; kept here too since that table isn't exported by the httpd package.
+var cgiStatusText = map[int]string{
+	200: "OK",
+	206: "Partial Content",
+	304: "Not Modified",
+	400: "Bad Request",
+	404: "Not Found",
+	416: "Requested Range Not Satisfiable",
+	500: "Internal Server Error",
+}
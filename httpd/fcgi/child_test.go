@@ -0,0 +1,150 @@
+package fcgi
+
+import (
+	"bufio"
+	"httpd/httpd"
+	"net"
+	"testing"
+	"time"
+)
+
+// echoHandler replies with its own request path, so a test can tell which
+// request a given FCGI_STDOUT record belongs to.
+type echoHandler struct{}
+
+func (echoHandler) ServeHTTP(w httpd.ResponseWriter, r *httpd.Request) {
+	w.Write([]byte("echo:" + r.URL.Path))
+}
+
+func encodeParam(name, value string) []byte {
+	return append(append([]byte{byte(len(name)), byte(len(value))}, name...), value...)
+}
+
+func writeBeginRequest(t *testing.T, c net.Conn, reqID uint16) {
+	t.Helper()
+	content := make([]byte, 8)
+	content[1] = roleResponder
+	if err := writeRecord(c, typeBeginRequest, reqID, content); err != nil {
+		t.Fatalf("writeRecord(begin %d): %v", reqID, err)
+	}
+}
+
+func writeParams(t *testing.T, c net.Conn, reqID uint16, path string) {
+	t.Helper()
+	var content []byte
+	content = append(content, encodeParam("REQUEST_METHOD", "GET")...)
+	content = append(content, encodeParam("REQUEST_URI", path)...)
+	if err := writeRecord(c, typeParams, reqID, content); err != nil {
+		t.Fatalf("writeRecord(params %d): %v", reqID, err)
+	}
+	if err := writeRecord(c, typeParams, reqID, nil); err != nil {
+		t.Fatalf("writeRecord(params-end %d): %v", reqID, err)
+	}
+}
+
+// TestServeConnMultiplexesInterleavedRequests drives serveConn through two
+// FCGI_BEGIN_REQUEST/FCGI_PARAMS/FCGI_STDIN sequences whose records are
+// interleaved on the wire, as a front-end web server multiplexing several
+// requests over one connection would send them, and checks each request id
+// gets back its own, non-interleaved FCGI_STDOUT/FCGI_END_REQUEST.
+func TestServeConnMultiplexesInterleavedRequests(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		serveConn(server, echoHandler{})
+		close(done)
+	}()
+
+	const reqA, reqB = 1, 2
+	writeBeginRequest(t, client, reqA)
+	writeBeginRequest(t, client, reqB)
+	writeParams(t, client, reqA, "/a")
+	writeParams(t, client, reqB, "/b")
+	if err := writeRecord(client, typeStdin, reqA, nil); err != nil {
+		t.Fatalf("writeRecord(stdin-end %d): %v", reqA, err)
+	}
+	if err := writeRecord(client, typeStdin, reqB, nil); err != nil {
+		t.Fatalf("writeRecord(stdin-end %d): %v", reqB, err)
+	}
+
+	got := map[uint16]string{}
+	ended := map[uint16]bool{}
+	br := bufio.NewReader(client)
+	for len(ended) < 2 {
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		rec, err := readRecord(br)
+		if err != nil {
+			t.Fatalf("readRecord: %v", err)
+		}
+		switch rec.h.Type {
+		case typeStdout:
+			got[rec.h.RequestId] += string(rec.content)
+		case typeEndRequest:
+			ended[rec.h.RequestId] = true
+		}
+	}
+
+	for id, path := range map[uint16]string{reqA: "/a", reqB: "/b"} {
+		want := "echo:" + path
+		body := got[id]
+		i := len(body) - len(want)
+		if i < 0 || body[i:] != want {
+			t.Errorf("request %d body = %q, want suffix %q", id, body, want)
+		}
+	}
+
+	client.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("serveConn did not return after client closed the connection")
+	}
+}
+
+// TestServeConnDropsStdinBeforeParamsComplete checks that a request whose
+// stdin stream ends before its params stream does (a malformed sequence per
+// the FastCGI spec) is never dispatched to the handler.
+func TestServeConnDropsStdinBeforeParamsComplete(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	dispatched := make(chan struct{}, 1)
+	h := httpd.Handler(echoHandlerFunc(func(w httpd.ResponseWriter, r *httpd.Request) {
+		dispatched <- struct{}{}
+		w.Write([]byte("should not run"))
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		serveConn(server, h)
+		close(done)
+	}()
+
+	writeBeginRequest(t, client, 1)
+	// Params never marked complete (no zero-length FCGI_PARAMS record)
+	// before stdin closes.
+	if err := writeRecord(client, typeStdin, 1, nil); err != nil {
+		t.Fatalf("writeRecord(stdin-end): %v", err)
+	}
+
+	client.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("serveConn did not return after client closed the connection")
+	}
+
+	select {
+	case <-dispatched:
+		t.Fatal("handler ran for a request whose params never completed")
+	default:
+	}
+}
+
+type echoHandlerFunc func(httpd.ResponseWriter, *httpd.Request)
+
+func (f echoHandlerFunc) ServeHTTP(w httpd.ResponseWriter, r *httpd.Request) {
+	f(w, r)
+}
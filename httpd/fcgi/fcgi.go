@@ -0,0 +1,182 @@
+// Package fcgi implements the FastCGI protocol, letting an httpd.Handler be
+// mounted behind a front-end web server (nginx, Apache) instead of this
+// module owning TLS/HTTP/2 termination itself.
+package fcgi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// FastCGI record types, see section 3.3 of the spec.
+const (
+	typeBeginRequest    = 1
+	typeAbortRequest    = 2
+	typeEndRequest      = 3
+	typeParams          = 4
+	typeStdin           = 5
+	typeStdout          = 6
+	typeStderr          = 7
+	typeData            = 8
+	typeGetValues       = 9
+	typeGetValuesResult = 10
+	typeUnknownType     = 11
+)
+
+// roles
+const (
+	roleResponder = 1
+)
+
+const (
+	flagKeepConn = 1
+)
+
+const fcgiVersion = 1
+
+var errCloseConn = errors.New("fcgi: connection should be closed")
+
+// header is the 8-byte record header that precedes every FastCGI record.
+type header struct {
+	Version       uint8
+	Type          uint8
+	RequestId     uint16
+	ContentLength uint16
+	PaddingLength uint8
+	Reserved      uint8
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var h header
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return h, err
+	}
+	h.Version = buf[0]
+	h.Type = buf[1]
+	h.RequestId = binary.BigEndian.Uint16(buf[2:4])
+	h.ContentLength = binary.BigEndian.Uint16(buf[4:6])
+	h.PaddingLength = buf[6]
+	h.Reserved = buf[7]
+	return h, nil
+}
+
+// record is one FastCGI record read off the wire: a header plus its content,
+// with padding already discarded.
+type record struct {
+	h       header
+	content []byte
+}
+
+func readRecord(r *bufio.Reader) (*record, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, h.ContentLength)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	if h.PaddingLength > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(h.PaddingLength)); err != nil {
+			return nil, err
+		}
+	}
+	return &record{h: h, content: content}, nil
+}
+
+// writeRecord writes typ/reqId/content as one or more FastCGI records,
+// splitting content into chunks no larger than 65535 bytes as required by
+// the wire format. A zero-length content still produces one (empty) record,
+// which is how FCGI_STDOUT/FCGI_STDERR streams are terminated.
+func writeRecord(w io.Writer, typ uint8, reqId uint16, content []byte) error {
+	const maxContent = 65535
+	for {
+		n := len(content)
+		if n > maxContent {
+			n = maxContent
+		}
+		buf := make([]byte, 8+n)
+		buf[0] = fcgiVersion
+		buf[1] = typ
+		binary.BigEndian.PutUint16(buf[2:4], reqId)
+		binary.BigEndian.PutUint16(buf[4:6], uint16(n))
+		copy(buf[8:], content[:n])
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		content = content[n:]
+		if len(content) == 0 {
+			return nil
+		}
+	}
+}
+
+// beginRequest is the content of a FCGI_BEGIN_REQUEST record.
+type beginRequest struct {
+	role  uint16
+	flags uint8
+}
+
+func parseBeginRequest(content []byte) (beginRequest, error) {
+	if len(content) < 8 {
+		return beginRequest{}, errors.New("fcgi: malformed begin-request record")
+	}
+	return beginRequest{
+		role:  binary.BigEndian.Uint16(content[0:2]),
+		flags: content[2],
+	}, nil
+}
+
+// parseParams decodes a FCGI_PARAMS record body (a sequence of
+// name/value-length-prefixed pairs) into a map, appending to dst.
+func parseParams(content []byte, dst map[string]string) error {
+	for len(content) > 0 {
+		nameLen, n := readParamLen(content)
+		if n == 0 {
+			return errors.New("fcgi: malformed params record")
+		}
+		content = content[n:]
+		valLen, n := readParamLen(content)
+		if n == 0 {
+			return errors.New("fcgi: malformed params record")
+		}
+		content = content[n:]
+		if len(content) < int(nameLen+valLen) {
+			return errors.New("fcgi: malformed params record")
+		}
+		dst[string(content[:nameLen])] = string(content[nameLen : nameLen+valLen])
+		content = content[nameLen+valLen:]
+	}
+	return nil
+}
+
+// readParamLen reads a FastCGI name/value length prefix: either one byte
+// (top bit clear) or four bytes (top bit set on the first byte).
+func readParamLen(b []byte) (length uint32, n int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	if b[0]>>7 == 0 {
+		return uint32(b[0]), 1
+	}
+	if len(b) < 4 {
+		return 0, 0
+	}
+	length = binary.BigEndian.Uint32(b) &^ (1 << 31)
+	return length, 4
+}
+
+// endRequest protocol statuses.
+const (
+	statusRequestComplete = 0
+)
+
+func writeEndRequest(w io.Writer, reqId uint16, appStatus uint32, protocolStatus uint8) error {
+	content := make([]byte, 8)
+	binary.BigEndian.PutUint32(content[0:4], appStatus)
+	content[4] = protocolStatus
+	return writeRecord(w, typeEndRequest, reqId, content)
+}
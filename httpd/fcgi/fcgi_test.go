@@ -0,0 +1,98 @@
+package fcgi
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestReadParamLen(t *testing.T) {
+	cases := []struct {
+		name       string
+		b          []byte
+		wantLength uint32
+		wantN      int
+	}{
+		{"empty", nil, 0, 0},
+		{"short form", []byte{42}, 42, 1},
+		{"long form", []byte{0x80, 0x00, 0x01, 0x00}, 256, 4},
+		{"truncated long form", []byte{0x80, 0x00}, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			length, n := readParamLen(c.b)
+			if length != c.wantLength || n != c.wantN {
+				t.Errorf("readParamLen(%v) = (%d, %d), want (%d, %d)", c.b, length, n, c.wantLength, c.wantN)
+			}
+		})
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	var content []byte
+	content = append(content, byte(len("REQUEST_METHOD")), byte(len("GET")))
+	content = append(content, []byte("REQUEST_METHOD")...)
+	content = append(content, []byte("GET")...)
+	content = append(content, byte(len("SCRIPT_NAME")), byte(len("/a")))
+	content = append(content, []byte("SCRIPT_NAME")...)
+	content = append(content, []byte("/a")...)
+
+	dst := make(map[string]string)
+	if err := parseParams(content, dst); err != nil {
+		t.Fatalf("parseParams: %v", err)
+	}
+	if dst["REQUEST_METHOD"] != "GET" {
+		t.Errorf("REQUEST_METHOD = %q, want GET", dst["REQUEST_METHOD"])
+	}
+	if dst["SCRIPT_NAME"] != "/a" {
+		t.Errorf("SCRIPT_NAME = %q, want /a", dst["SCRIPT_NAME"])
+	}
+}
+
+func TestParseParamsMalformed(t *testing.T) {
+	dst := make(map[string]string)
+	if err := parseParams([]byte{5, 1, 'a'}, dst); err == nil {
+		t.Fatal("parseParams: want error for truncated value, got nil")
+	}
+}
+
+func TestParseBeginRequest(t *testing.T) {
+	content := []byte{0x00, 0x01, flagKeepConn, 0, 0, 0, 0, 0}
+	br, err := parseBeginRequest(content)
+	if err != nil {
+		t.Fatalf("parseBeginRequest: %v", err)
+	}
+	if br.role != roleResponder {
+		t.Errorf("role = %d, want %d", br.role, roleResponder)
+	}
+	if br.flags != flagKeepConn {
+		t.Errorf("flags = %d, want %d", br.flags, flagKeepConn)
+	}
+	if _, err := parseBeginRequest(content[:4]); err == nil {
+		t.Fatal("parseBeginRequest: want error for short content, got nil")
+	}
+}
+
+func TestWriteRecordReadRecordRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	content := bytes.Repeat([]byte("x"), 70000)
+	if err := writeRecord(&buf, typeStdout, 1, content); err != nil {
+		t.Fatalf("writeRecord: %v", err)
+	}
+
+	bufr := bufio.NewReader(&buf)
+	var got []byte
+	for {
+		rec, err := readRecord(bufr)
+		if err != nil {
+			t.Fatalf("readRecord: %v", err)
+		}
+		got = append(got, rec.content...)
+		if len(got) >= len(content) {
+			break
+		}
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("round-tripped content mismatch: got %d bytes, want %d", len(got), len(content))
+	}
+}
@@ -0,0 +1,208 @@
+package httpd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testResponseWriter is a minimal ResponseWriter that records what was
+// written, for exercising handler-level code like ServeContent/ServeFile
+// without a real connection.
+type testResponseWriter struct {
+	header Header
+	code   int
+	body   strings.Builder
+}
+
+func newTestResponseWriter() *testResponseWriter {
+	return &testResponseWriter{header: make(Header)}
+}
+
+func (w *testResponseWriter) Header() Header { return w.header }
+
+func (w *testResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *testResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = 200
+	}
+	return w.body.Write(p)
+}
+
+func TestParseRange(t *testing.T) {
+	const size = int64(100)
+	cases := []struct {
+		name    string
+		header  string
+		want    []httpRange
+		wantErr bool
+	}{
+		{"no header", "", nil, false},
+		{"single range", "bytes=0-49", []httpRange{{start: 0, length: 50}}, false},
+		{"open-ended range", "bytes=90-", []httpRange{{start: 90, length: 10}}, false},
+		{"suffix range", "bytes=-10", []httpRange{{start: 90, length: 10}}, false},
+		{"suffix range larger than size", "bytes=-1000", []httpRange{{start: 0, length: 100}}, false},
+		{"end clamped to size", "bytes=50-1000", []httpRange{{start: 50, length: 50}}, false},
+		{"multiple ranges", "bytes=0-9,20-29", []httpRange{{start: 0, length: 10}, {start: 20, length: 10}}, false},
+		{"missing prefix", "0-49", nil, true},
+		{"start past size", "bytes=200-300", nil, true},
+		{"start after end", "bytes=40-20", nil, true},
+		{"garbage", "bytes=abc-def", nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseRange(c.header, size)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("parseRange(%q) = %v, want error", c.header, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRange(%q): unexpected error %v", c.header, err)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("parseRange(%q) = %v, want %v", c.header, got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("parseRange(%q)[%d] = %v, want %v", c.header, i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPRangeContentRange(t *testing.T) {
+	ra := httpRange{start: 10, length: 5}
+	if got, want := ra.contentRange(100), "bytes 10-14/100"; got != want {
+		t.Errorf("contentRange = %q, want %q", got, want)
+	}
+}
+
+func TestServeContentFullBody(t *testing.T) {
+	w := newTestResponseWriter()
+	r := &Request{Header: make(Header)}
+	ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader("0123456789"))
+
+	if w.code != 200 {
+		t.Fatalf("code = %d, want 200", w.code)
+	}
+	if w.body.String() != "0123456789" {
+		t.Errorf("body = %q, want %q", w.body.String(), "0123456789")
+	}
+	if got := w.header.Get("Content-Length"); got != "10" {
+		t.Errorf("Content-Length = %q, want %q", got, "10")
+	}
+}
+
+func TestServeContentSingleRange(t *testing.T) {
+	w := newTestResponseWriter()
+	r := &Request{Header: Header{"Range": {"bytes=2-4"}}}
+	ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader("0123456789"))
+
+	if w.code != 206 {
+		t.Fatalf("code = %d, want 206", w.code)
+	}
+	if w.body.String() != "234" {
+		t.Errorf("body = %q, want %q", w.body.String(), "234")
+	}
+	if got := w.header.Get("Content-Range"); got != "bytes 2-4/10" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes 2-4/10")
+	}
+}
+
+func TestServeContentInvalidRange(t *testing.T) {
+	w := newTestResponseWriter()
+	r := &Request{Header: Header{"Range": {"bytes=50-60"}}}
+	ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader("0123456789"))
+
+	if w.code != 416 {
+		t.Fatalf("code = %d, want 416", w.code)
+	}
+	if got := w.header.Get("Content-Range"); got != "bytes */10" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes */10")
+	}
+}
+
+func TestServeContentNotModified(t *testing.T) {
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := newTestResponseWriter()
+	r := &Request{Header: Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}}}
+	ServeContent(w, r, "file.txt", modTime, strings.NewReader("data"))
+
+	if w.code != 304 {
+		t.Fatalf("code = %d, want 304", w.code)
+	}
+	if w.body.String() != "" {
+		t.Errorf("body = %q, want empty", w.body.String())
+	}
+}
+
+func TestServeContentMultipleRanges(t *testing.T) {
+	w := newTestResponseWriter()
+	r := &Request{Header: Header{"Range": {"bytes=0-1,3-4"}}}
+	ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader("0123456789"))
+
+	if w.code != 206 {
+		t.Fatalf("code = %d, want 206", w.code)
+	}
+	ct := w.header.Get("Content-Type")
+	const prefix = "multipart/byteranges; boundary="
+	if !strings.HasPrefix(ct, prefix) {
+		t.Fatalf("Content-Type = %q, want prefix %q", ct, prefix)
+	}
+	boundary := strings.TrimPrefix(ct, prefix)
+
+	body := w.body.String()
+	for _, want := range []string{
+		"--" + boundary + "\r\n",
+		"Content-Range: bytes 0-1/10\r\n",
+		"01",
+		"Content-Range: bytes 3-4/10\r\n",
+		"34",
+		"--" + boundary + "--\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q, got %q", want, body)
+		}
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(name, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	w := newTestResponseWriter()
+	r := &Request{Header: make(Header)}
+	ServeFile(w, r, name)
+
+	if w.code != 200 {
+		t.Fatalf("code = %d, want 200", w.code)
+	}
+	if w.body.String() != "hello world" {
+		t.Errorf("body = %q, want %q", w.body.String(), "hello world")
+	}
+}
+
+func TestServeFileMissing(t *testing.T) {
+	w := newTestResponseWriter()
+	r := &Request{Header: make(Header)}
+	ServeFile(w, r, filepath.Join(t.TempDir(), "missing.txt"))
+
+	if w.code != 404 {
+		t.Fatalf("code = %d, want 404", w.code)
+	}
+}
@@ -0,0 +1,33 @@
+package httpd
+
+import "net/textproto"
+
+// Header represents the key-value pairs of an HTTP header. Keys are stored
+// in canonical MIME header form (e.g. "Content-Type"), the same form
+// net/http.Header uses, so Get/Set/Add/Del are case-insensitive.
+type Header map[string][]string
+
+// Get returns the first value associated with key, or "" if there is none.
+func (h Header) Get(key string) string {
+	v := h[textproto.CanonicalMIMEHeaderKey(key)]
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
+
+// Set replaces any existing values of key with value.
+func (h Header) Set(key, value string) {
+	h[textproto.CanonicalMIMEHeaderKey(key)] = []string{value}
+}
+
+// Add appends value to the values associated with key.
+func (h Header) Add(key, value string) {
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	h[key] = append(h[key], value)
+}
+
+// Del removes the values associated with key.
+func (h Header) Del(key string) {
+	delete(h, textproto.CanonicalMIMEHeaderKey(key))
+}
@@ -0,0 +1,249 @@
+// Package httputil provides HTTP utility functions built on top of httpd,
+// starting with a ReverseProxy.
+package httputil
+
+import (
+	"bufio"
+	"fmt"
+	"httpd/httpd"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// hopHeaders are meaningful only for a single transport-level connection and
+// must never be forwarded by a proxy (RFC 7230 section 6.1).
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailers",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Director rewrites a copy of the inbound request in place so that it
+// targets the upstream a ReverseProxy should forward it to (typically
+// setting r.URL.Host/Scheme).
+type Director func(r *httpd.Request)
+
+// ReverseProxy is an httpd.Handler that rewrites each request with Director,
+// forwards it to the resulting host over a pooled connection, and streams
+// the upstream's response back to the client.
+type ReverseProxy struct {
+	Director Director
+
+	// FlushInterval, if positive, periodically flushes the response
+	// while copying the upstream's body, so streaming upstreams (SSE,
+	// long-poll) reach the client without waiting for EOF.
+	FlushInterval time.Duration
+
+	mu    sync.Mutex
+	conns map[string]net.Conn
+}
+
+func (p *ReverseProxy) ServeHTTP(w httpd.ResponseWriter, r *httpd.Request) {
+	outReq := *r
+	outHeader := make(httpd.Header, len(r.Header))
+	for k, v := range r.Header {
+		outHeader[k] = append([]string(nil), v...)
+	}
+	outReq.Header = outHeader
+	if r.URL != nil {
+		outURL := *r.URL
+		outReq.URL = &outURL
+	}
+	p.Director(&outReq)
+
+	removeHopHeaders(outReq.Header)
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		outReq.Header.Add("X-Forwarded-For", host)
+	} else {
+		outReq.Header.Add("X-Forwarded-For", r.RemoteAddr)
+	}
+
+	upstream, err := p.dial(outReq.URL.Host)
+	if err != nil {
+		w.WriteHeader(502)
+		return
+	}
+
+	if err := writeRequest(upstream, &outReq); err != nil {
+		upstream.Close()
+		w.WriteHeader(502)
+		return
+	}
+
+	bufr := bufio.NewReader(upstream)
+	statusCode, header, err := readResponseHead(bufr)
+	if err != nil {
+		upstream.Close()
+		w.WriteHeader(502)
+		return
+	}
+	closeUpstream := header.Get("Connection") == "close"
+	removeHopHeaders(header)
+
+	respHeader := w.Header()
+	for k, vs := range header {
+		for _, v := range vs {
+			respHeader.Add(k, v)
+		}
+	}
+	w.WriteHeader(statusCode)
+
+	p.copyBody(w, responseBody(bufr, header))
+
+	if closeUpstream {
+		upstream.Close()
+	} else {
+		p.release(outReq.URL.Host, upstream)
+	}
+}
+
+func (p *ReverseProxy) copyBody(w httpd.ResponseWriter, body io.Reader) {
+	flusher, canFlush := w.(httpd.Flusher)
+	if !canFlush || p.FlushInterval <= 0 {
+		io.Copy(w, body)
+		return
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(p.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flusher.Flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+	io.Copy(w, body)
+	flusher.Flush()
+}
+
+func (p *ReverseProxy) dial(addr string) (net.Conn, error) {
+	p.mu.Lock()
+	if c, ok := p.conns[addr]; ok {
+		delete(p.conns, addr)
+		p.mu.Unlock()
+		return c, nil
+	}
+	p.mu.Unlock()
+	return net.Dial("tcp", addr)
+}
+
+func (p *ReverseProxy) release(addr string, c net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns == nil {
+		p.conns = make(map[string]net.Conn)
+	}
+	if _, busy := p.conns[addr]; busy {
+		c.Close()
+		return
+	}
+	p.conns[addr] = c
+}
+
+func removeHopHeaders(h httpd.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// writeRequest re-serializes r onto w as an HTTP/1.1 request, chunk-encoding
+// the body when its length isn't already known.
+func writeRequest(w io.Writer, r *httpd.Request) error {
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "%s %s HTTP/1.1\r\n", r.Method, r.URL.RequestURI()); err != nil {
+		return err
+	}
+	if r.Header.Get("Host") == "" {
+		fmt.Fprintf(bw, "Host: %s\r\n", r.URL.Host)
+	}
+	chunked := r.Header.Get("Content-Length") == ""
+	if chunked {
+		bw.WriteString("Transfer-Encoding: chunked\r\n")
+	}
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			fmt.Fprintf(bw, "%s: %s\r\n", k, v)
+		}
+	}
+	bw.WriteString("\r\n")
+
+	if r.Body != nil {
+		var err error
+		if chunked {
+			err = writeChunkedBody(bw, r.Body)
+		} else {
+			_, err = io.Copy(bw, r.Body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writeChunkedBody(w io.Writer, r io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := fmt.Fprintf(w, "%x\r\n", n); werr != nil {
+				return werr
+			}
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if _, werr := io.WriteString(w, "\r\n"); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			_, werr := io.WriteString(w, "0\r\n\r\n")
+			return werr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readResponseHead parses the status line and headers of an upstream's
+// HTTP/1.1 response using the same line/header helpers httpd uses for
+// requests.
+func readResponseHead(bufr *bufio.Reader) (statusCode int, header httpd.Header, err error) {
+	line, err := httpd.ReadLine(bufr)
+	if err != nil {
+		return
+	}
+	var proto string
+	if _, err = fmt.Sscanf(string(line), "%s %d", &proto, &statusCode); err != nil {
+		return
+	}
+	header, err = httpd.ReadHeader(bufr)
+	return
+}
+
+func responseBody(bufr *bufio.Reader, header httpd.Header) io.Reader {
+	if header.Get("Transfer-Encoding") == "chunked" {
+		return httpd.NewChunkedReader(bufr)
+	}
+	if cl := header.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil {
+			return io.LimitReader(bufr, n)
+		}
+	}
+	return bufr
+}
@@ -0,0 +1,85 @@
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"httpd/httpd"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRemoveHopHeaders(t *testing.T) {
+	h := make(httpd.Header)
+	h.Set("Connection", "keep-alive")
+	h.Set("Content-Type", "text/plain")
+	removeHopHeaders(h)
+	if h.Get("Connection") != "" {
+		t.Errorf("Connection header should have been removed, got %q", h.Get("Connection"))
+	}
+	if h.Get("Content-Type") != "text/plain" {
+		t.Errorf("Content-Type header should survive, got %q", h.Get("Content-Type"))
+	}
+}
+
+func TestReadResponseHead(t *testing.T) {
+	raw := "HTTP/1.1 206 Partial Content\r\nContent-Range: bytes 0-1/2\r\nContent-Length: 2\r\n\r\nhi"
+	bufr := bufio.NewReader(strings.NewReader(raw))
+	statusCode, header, err := readResponseHead(bufr)
+	if err != nil {
+		t.Fatalf("readResponseHead: %v", err)
+	}
+	if statusCode != 206 {
+		t.Errorf("statusCode = %d, want 206", statusCode)
+	}
+	if got := header.Get("Content-Range"); got != "bytes 0-1/2" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes 0-1/2")
+	}
+}
+
+func TestResponseBodyChunked(t *testing.T) {
+	header := make(httpd.Header)
+	header.Set("Transfer-Encoding", "chunked")
+	bufr := bufio.NewReader(strings.NewReader("5\r\nhello\r\n0\r\n\r\n"))
+	body, err := io.ReadAll(responseBody(bufr, header))
+	if err != nil {
+		t.Fatalf("read chunked body: %v", err)
+	}
+	if string(body) != "hello" {
+		t.Errorf("body = %q, want %q", body, "hello")
+	}
+}
+
+func TestResponseBodyContentLength(t *testing.T) {
+	header := make(httpd.Header)
+	header.Set("Content-Length", "5")
+	bufr := bufio.NewReader(strings.NewReader("hellotrailing-garbage"))
+	body, err := io.ReadAll(responseBody(bufr, header))
+	if err != nil {
+		t.Fatalf("read content-length body: %v", err)
+	}
+	if string(body) != "hello" {
+		t.Errorf("body = %q, want %q", body, "hello")
+	}
+}
+
+func TestWriteRequestChunksUnsizedBody(t *testing.T) {
+	r := &httpd.Request{
+		Method: "POST",
+		URL:    &url.URL{Host: "upstream.example", Path: "/p"},
+		Header: make(httpd.Header),
+		Body:   strings.NewReader("payload"),
+	}
+	var buf bytes.Buffer
+	if err := writeRequest(&buf, r); err != nil {
+		t.Fatalf("writeRequest: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Transfer-Encoding: chunked\r\n") {
+		t.Errorf("expected chunked encoding in request, got %q", out)
+	}
+	if !strings.Contains(out, "7\r\npayload\r\n0\r\n\r\n") {
+		t.Errorf("expected chunk-encoded body, got %q", out)
+	}
+}
@@ -13,6 +13,11 @@ import (
 
 const bufSize = 4096
 
+const (
+	defaultNonFileMaxMemory int64 = 10 << 20
+	defaultFileMaxMemory    int64 = 30 << 20
+)
+
 type MultipartReader struct {
 	bufr                 *bufio.Reader
 	occurEofErr          bool
@@ -22,6 +27,13 @@ type MultipartReader struct {
 	dashBoundaryDash     []byte
 	curPart              *Part
 	crlf                 [2]byte
+
+	// NonFileMaxMemory and FileMaxMemory cap how much of the non-file and
+	// file parts, respectively, in total across all parts of each kind,
+	// ReadForm will buffer in memory. ReadFormStreaming uses only
+	// NonFileMaxMemory. Zero means the package defaults are used.
+	NonFileMaxMemory int64
+	FileMaxMemory    int64
 }
 
 func NewMultipartReader(r io.Reader, boundary string) *MultipartReader {
@@ -42,8 +54,14 @@ func (mr *MultipartReader) ReadForm() (mf *MultipartForm, err error) {
 	}
 
 	var part *Part
-	var nonFileMaxMemory int64 = 10 << 20
-	var fileMaxMemory int64 = 30 << 20
+	nonFileMaxMemory := mr.NonFileMaxMemory
+	if nonFileMaxMemory == 0 {
+		nonFileMaxMemory = defaultNonFileMaxMemory
+	}
+	fileMaxMemory := mr.FileMaxMemory
+	if fileMaxMemory == 0 {
+		fileMaxMemory = defaultFileMaxMemory
+	}
 	for {
 		part, err = mr.NextPart()
 		if err == io.EOF {
@@ -112,6 +130,55 @@ func (mr *MultipartReader) ReadForm() (mf *MultipartForm, err error) {
 	return mf, nil
 }
 
+// ReadFormStreaming is like ReadForm except file parts are never buffered
+// into memory or a temp file: each one is handed to onFile as soon as its
+// headers are parsed, and the caller is responsible for reading it (e.g.
+// io.Copy to disk, S3, a hash) before returning. Non-file parts are still
+// collected into the returned MultipartForm's Value map, bounded by
+// NonFileMaxMemory; the returned form's File map is always empty.
+func (mr *MultipartReader) ReadFormStreaming(onFile func(part *Part) error) (mf *MultipartForm, err error) {
+	mf = &MultipartForm{
+		Value: make(map[string]string),
+		File:  make(map[string]*FileHeader),
+	}
+
+	nonFileMaxMemory := mr.NonFileMaxMemory
+	if nonFileMaxMemory == 0 {
+		nonFileMaxMemory = defaultNonFileMaxMemory
+	}
+
+	var part *Part
+	for {
+		part, err = mr.NextPart()
+		if err == io.EOF {
+			return mf, nil
+		}
+		if err != nil {
+			return
+		}
+		if part.FormName() == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			var buff bytes.Buffer
+			var n int64
+			n, err = io.CopyN(&buff, part, nonFileMaxMemory+1)
+			if err != nil && err != io.EOF {
+				return
+			}
+			nonFileMaxMemory -= n
+			if nonFileMaxMemory < 0 {
+				return nil, errors.New("multipart: message too large")
+			}
+			mf.Value[part.FormName()] = buff.String()
+			continue
+		}
+		if err = onFile(part); err != nil {
+			return
+		}
+	}
+}
+
 func (mr *MultipartReader) NextPart() (p *Part, err error) {
 	if mr.curPart != nil {
 		if err = mr.curPart.Close(); err != nil {
@@ -0,0 +1,94 @@
+package httpd
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const testBoundary = "xxBOUNDARYxx"
+
+func buildMultipart(parts ...string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString("--" + testBoundary + "\r\n")
+		b.WriteString(p)
+	}
+	b.WriteString("--" + testBoundary + "--")
+	return b.String()
+}
+
+func TestMultipartReaderReadForm(t *testing.T) {
+	body := buildMultipart(
+		"Content-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n",
+		"Content-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\n\r\nfile-contents\r\n",
+	)
+	mr := NewMultipartReader(strings.NewReader(body), testBoundary)
+	mf, err := mr.ReadForm()
+	if err != nil {
+		t.Fatalf("ReadForm: %v", err)
+	}
+	if got := mf.Value["title"]; got != "hello" {
+		t.Errorf("Value[title] = %q, want %q", got, "hello")
+	}
+	fh, ok := mf.File["file"]
+	if !ok {
+		t.Fatalf("File[file] missing")
+	}
+	if fh.Size != len("file-contents") {
+		t.Errorf("file size = %d, want %d", fh.Size, len("file-contents"))
+	}
+}
+
+func TestMultipartReaderReadFormStreaming(t *testing.T) {
+	body := buildMultipart(
+		"Content-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n",
+		"Content-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\n\r\nfile-contents\r\n",
+	)
+	mr := NewMultipartReader(strings.NewReader(body), testBoundary)
+
+	var streamed []byte
+	mf, err := mr.ReadFormStreaming(func(part *Part) error {
+		var err error
+		streamed, err = readAll(part)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ReadFormStreaming: %v", err)
+	}
+	if got := mf.Value["title"]; got != "hello" {
+		t.Errorf("Value[title] = %q, want %q", got, "hello")
+	}
+	if string(streamed) != "file-contents" {
+		t.Errorf("streamed file part = %q, want %q", streamed, "file-contents")
+	}
+	if len(mf.File) != 0 {
+		t.Errorf("ReadFormStreaming should leave File empty, got %d entries", len(mf.File))
+	}
+}
+
+func TestMultipartReaderReadFormNonFileMaxMemory(t *testing.T) {
+	body := buildMultipart(
+		"Content-Disposition: form-data; name=\"title\"\r\n\r\ntoolongvalue\r\n",
+	)
+	mr := NewMultipartReader(strings.NewReader(body), testBoundary)
+	mr.NonFileMaxMemory = 4
+	if _, err := mr.ReadForm(); err == nil {
+		t.Fatal("ReadForm: want error when a non-file part exceeds NonFileMaxMemory, got nil")
+	}
+}
+
+func readAll(p *Part) ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	tmp := make([]byte, 16)
+	for {
+		n, err := p.Read(tmp)
+		buf = append(buf, tmp[:n]...)
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package httpd
+
+import (
+	"bufio"
+	"io"
+)
+
+// ReadLine reads a single CRLF- or LF-terminated line from bufr, the same
+// helper readRequest uses for the request line and header lines. A line
+// longer than bufr's buffer is joined back together from its fragments. It
+// is exported so other packages in this module that speak HTTP/1.1 over
+// their own connections (httputil) can reuse it instead of duplicating it.
+func ReadLine(bufr *bufio.Reader) ([]byte, error) {
+	return readLine(bufr)
+}
+
+// ReadHeader parses a block of "Key: Value" lines, up to the terminating
+// blank line, into a Header.
+func ReadHeader(bufr *bufio.Reader) (Header, error) {
+	return readHeader(bufr)
+}
+
+// NewChunkedReader returns an io.Reader that decodes an HTTP/1.1 chunked
+// transfer-encoded stream read from bufr, the same decoder this package
+// uses for chunked request bodies.
+func NewChunkedReader(bufr *bufio.Reader) io.Reader {
+	return &chunkReader{bufr: bufr}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/textproto"
 	"net/url"
 	"os"
 	"strconv"
@@ -14,15 +15,20 @@ import (
 )
 
 type Request struct {
-	Method         string
-	URL            *url.URL
-	Proto          string
-	Header         Header
-	Body           io.Reader
+	Method string
+	URL    *url.URL
+	Proto  string
+	Header Header
+	Body   io.Reader
+	// Trailer holds trailer headers received after a chunked body, filtered
+	// to the names announced by the request's Trailer header. It is only
+	// populated once Body has been fully read.
+	Trailer        Header
 	RemoteAddr     string
 	RequestURI     string
 	conn           *conn
 	cookies        map[string]string
+	cookieList     []*Cookie
 	queryString    map[string]string
 	contentType    string
 	boundary       string
@@ -218,7 +224,27 @@ func (r *Request) MultipartReader() (*MultipartReader, error) {
 	if r.boundary == "" {
 		return nil, errors.New("no boundary detected")
 	}
-	return NewMultipartReader(r.Body, r.boundary), nil
+	mr := NewMultipartReader(r.Body, r.boundary)
+	if r.conn != nil && r.conn.svr != nil {
+		mr.NonFileMaxMemory = r.conn.svr.NonFileMaxMemory
+		mr.FileMaxMemory = r.conn.svr.FileMaxMemory
+	}
+	return mr, nil
+}
+
+// MultipartStream parses the request as multipart/form-data without
+// buffering file parts into memory or a temp file first: onFile is called
+// with each file Part as soon as its headers arrive, so the handler can
+// io.Copy it straight to its destination (disk, S3, a hash, ...). Form
+// value parts are still collected into the returned MultipartForm's Value
+// map. Callers using this must not also call MultipartForm/PostForm/FormFile
+// on the same request, since the body can only be streamed once.
+func (r *Request) MultipartStream(onFile func(part *Part) error) (*MultipartForm, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	return mr.ReadFormStreaming(onFile)
 }
 
 func (r *Request) parseQuery() {
@@ -234,7 +260,7 @@ func (r *Request) setupBody() {
 	if r.Method != "POST" && r.Method != "PUT" {
 		r.Body = &eofReader{}
 	} else if r.chunked() {
-		r.Body = &chunkReader{bufr: r.conn.bufr}
+		r.Body = &chunkReader{bufr: r.conn.bufr, req: r}
 		r.fixExpectContinueReader()
 	} else if cl := r.Header.Get("Content-Length"); cl != "" {
 		contentLength, err := strconv.ParseInt(cl, 10, 64)
@@ -271,9 +297,25 @@ func (r *Request) finishRequest(resp *response) (err error) {
 	}
 
 	if resp.chunking {
-		_, err = resp.c.bufw.WriteString("0\r\n\r\n")
-		if err != nil {
-			return
+		if len(resp.trailer) == 0 {
+			_, err = resp.c.bufw.WriteString("0\r\n\r\n")
+			if err != nil {
+				return
+			}
+		} else {
+			if _, err = resp.c.bufw.WriteString("0\r\n"); err != nil {
+				return
+			}
+			for k, v := range resp.trailer {
+				for _, value := range v {
+					if _, err = resp.c.bufw.WriteString(k + ": " + value + "\r\n"); err != nil {
+						return
+					}
+				}
+			}
+			if _, err = resp.c.bufw.WriteString("\r\n"); err != nil {
+				return
+			}
 		}
 	}
 
@@ -334,7 +376,10 @@ func (r *Request) parseCookies() {
 			if index == -1 {
 				continue
 			}
-			r.cookies[strings.TrimSpace(kvs[i][:index])] = strings.TrimSpace(kvs[i][index+1:])
+			name := strings.TrimSpace(kvs[i][:index])
+			value := unquoteCookieValue(strings.TrimSpace(kvs[i][index+1:]))
+			r.cookies[name] = value
+			r.cookieList = append(r.cookieList, &Cookie{Name: name, Value: value})
 		}
 	}
 	return
@@ -388,7 +433,8 @@ func readHeader(bufr *bufio.Reader) (Header, error) {
 		if i == len(line)-1 {
 			continue
 		}
-		k, v := string(line[:i]), strings.TrimSpace(string(line[i+1:]))
+		k := textproto.CanonicalMIMEHeaderKey(string(line[:i]))
+		v := strings.TrimSpace(string(line[i+1:]))
 		header[k] = append(header[k], v)
 	}
 	return header, nil
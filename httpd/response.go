@@ -3,6 +3,7 @@ package httpd
 import (
 	"bufio"
 	"fmt"
+	"sync"
 )
 
 type response struct {
@@ -15,6 +16,13 @@ type response struct {
 
 	handlerDone bool
 
+	// writeMu serializes Write and Flush, both of which ultimately touch
+	// bufw (and, via chunkWriter, c.bufw) — bufio.Writer is not safe for
+	// concurrent use, and a Flusher may be called from a goroutine (e.g.
+	// httputil.ReverseProxy's FlushInterval ticker) while the handler is
+	// still writing the body.
+	writeMu sync.Mutex
+
 	bufw *bufio.Writer
 	cw   *chunkWriter
 
@@ -23,6 +31,8 @@ type response struct {
 	closeAfterReply bool
 
 	chunking bool
+
+	trailer Header
 }
 
 type ResponseWriter interface {
@@ -53,7 +63,9 @@ func setupResponse(c *conn, req *Request) *response {
 }
 
 func (w *response) Write(p []byte) (int, error) {
-	n, err := w.c.bufw.Write(p)
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	n, err := w.bufw.Write(p)
 	if err != nil {
 		w.closeAfterReply = true
 	}
@@ -71,3 +83,36 @@ func (w *response) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 	w.wroteHeader = true
 }
+
+// Flusher is implemented by ResponseWriters that can push buffered data to
+// the client immediately, instead of waiting for the handler to finish or
+// the buffer to fill. Long-lived responses (SSE, long-poll proxying) need
+// this to make progress.
+type Flusher interface {
+	Flush() error
+}
+
+func (w *response) Flush() error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if err := w.bufw.Flush(); err != nil {
+		return err
+	}
+	return w.c.bufw.Flush()
+}
+
+// Trailers is implemented by ResponseWriters that support HTTP/1.1
+// trailers. A streaming handler declares the trailer names it intends to
+// send by setting keys in TrailerHeader() before the first Write, then
+// fills in their values once it has finished writing the body; finishRequest
+// sends them after the final chunk.
+type Trailers interface {
+	TrailerHeader() Header
+}
+
+func (w *response) TrailerHeader() Header {
+	if w.trailer == nil {
+		w.trailer = make(Header)
+	}
+	return w.trailer
+}
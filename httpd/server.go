@@ -0,0 +1,64 @@
+package httpd
+
+import "net"
+
+// Handler responds to a single HTTP request.
+type Handler interface {
+	ServeHTTP(w ResponseWriter, r *Request)
+}
+
+// Server defines the parameters for running an HTTP server.
+type Server struct {
+	Addr    string
+	Handler Handler
+
+	// NonFileMaxMemory caps how many bytes of non-file multipart parts, in
+	// total across all of them, ReadForm will buffer in memory before
+	// rejecting the request. Zero means the defaultNonFileMaxMemory default
+	// is used.
+	NonFileMaxMemory int64
+	// FileMaxMemory caps how many bytes of file multipart parts, in total
+	// across all of them, ReadForm will keep in memory; a file part that
+	// does not fit in what remains is spilled to a temp file. Zero means
+	// the defaultFileMaxMemory default is used.
+	FileMaxMemory int64
+
+	// CookieSignKey, when set, is the HMAC-SHA256 key handlers can pass to
+	// SignedCookie/VerifyCookie to issue and authenticate tamper-evident
+	// cookies.
+	CookieSignKey []byte
+}
+
+// ListenAndServe listens on srv.Addr and then calls Serve to handle
+// connections on incoming requests.
+func (srv *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts incoming connections on l, creating a new connection goroutine
+// for each one.
+func (srv *Server) Serve(l net.Listener) error {
+	defer l.Close()
+	for {
+		rwc, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		c := newConn(rwc, srv)
+		go c.serve()
+	}
+}
+
+var statusText = map[int]string{
+	200: "OK",
+	206: "Partial Content",
+	304: "Not Modified",
+	400: "Bad Request",
+	404: "Not Found",
+	416: "Requested Range Not Satisfiable",
+	500: "Internal Server Error",
+}